@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gh-repo-research-api/internal/builder"
+	"gh-repo-research-api/internal/database"
+)
+
+// batchSize is how many repositories are paged out of the store at a
+// time while queuing build jobs - large enough to keep the worker pool
+// fed, small enough not to hold every flagged repo's Dockerfiles in
+// memory at once.
+const batchSize = 100
+
+// abortSignal returns a channel that fires once on SIGINT/SIGTERM, so
+// an in-flight build run can clean up its spawned containers/images
+// instead of leaving them behind.
+func abortSignal() chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	return ch
+}
+
+// collectJobs pages through every repository flagged has_dockerfile and
+// returns one builder.Job per Dockerfile found on it.
+func collectJobs(db database.Store) ([]builder.Job, error) {
+	var jobs []builder.Job
+
+	for offset := 0; ; offset += batchSize {
+		repos, err := db.GetRepositoriesWithDockerfile(batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		if len(repos) == 0 {
+			break
+		}
+
+		for _, repo := range repos {
+			dockerfiles, err := db.GetDockerfilesByRepository(repo.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list dockerfiles for %s: %w", repo.NameWithOwner, err)
+			}
+
+			for _, df := range dockerfiles {
+				jobs = append(jobs, builder.Job{
+					RepositoryID:   repo.ID,
+					DockerfileID:   df.ID,
+					RepoURL:        repo.URL,
+					RepoFullName:   repo.NameWithOwner,
+					DockerfilePath: df.Path,
+				})
+			}
+		}
+
+		if len(repos) < batchSize {
+			break
+		}
+	}
+
+	return jobs, nil
+}
+
+func main() {
+	var (
+		storeDriver = flag.String("store", "postgres", "Storage backend: postgres or sqlite")
+		databaseURL = flag.String("database-url", "", "Postgres connection string (or set DATABASE_URL)")
+		sqlitePath  = flag.String("sqlite-path", "", "SQLite database file path (or set SQLITE_PATH)")
+		concurrency = flag.Int("concurrency", 4, "Number of Dockerfiles to build concurrently")
+		timeout     = flag.Duration("timeout", 10*time.Minute, "Per-build timeout")
+		logsDir     = flag.String("logs-dir", "build-logs", "Directory to write per-repository build logs to")
+		dryRun      = flag.Bool("dry-run", false, "Only validate that each Dockerfile parses; never invoke the Docker daemon")
+	)
+	flag.Parse()
+
+	dsn := *databaseURL
+	if *storeDriver == "sqlite" {
+		dsn = *sqlitePath
+	}
+	db, err := database.NewStore(*storeDriver, dsn)
+	if err != nil {
+		log.Fatalf("Failed to open %s store: %v", *storeDriver, err)
+	}
+	defer db.Close()
+
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	jobs, err := collectJobs(db)
+	if err != nil {
+		log.Fatalf("Failed to collect build jobs: %v", err)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No Dockerfiles found to build (nothing flagged has_dockerfile yet).")
+		return
+	}
+	fmt.Printf("Queued %d Dockerfile build(s) across flagged repositories.\n", len(jobs))
+
+	b, err := builder.New(*logsDir, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to initialize builder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := abortSignal()
+	go func() {
+		<-sigCh
+		fmt.Println("\nAborting... cleaning up spawned containers and images.")
+		if err := b.Abort(context.Background()); err != nil {
+			log.Printf("Failed to clean up after abort: %v", err)
+		}
+		cancel()
+	}()
+
+	var succeeded, failed int
+	for res := range b.Run(ctx, jobs, *concurrency, *timeout) {
+		result := database.BuildResult{
+			RepositoryID: res.RepositoryID,
+			DockerfileID: res.DockerfileID,
+			Status:       res.Status,
+			DurationMs:   res.Duration.Milliseconds(),
+		}
+		if res.SizeBytes > 0 {
+			result.ImageSizeBytes = &res.SizeBytes
+		}
+		if res.LayerCount > 0 {
+			result.LayerCount = &res.LayerCount
+		}
+		if res.Err != nil {
+			msg := res.Err.Error()
+			result.ErrorMessage = &msg
+		}
+
+		if err := db.InsertBuildResult(result); err != nil {
+			log.Printf("Failed to save build result for %s (%s): %v", res.RepoFullName, res.DockerfilePath, err)
+		}
+
+		if res.Err != nil {
+			failed++
+			fmt.Printf("  - %s (%s): %s - %v\n", res.RepoFullName, res.DockerfilePath, res.Status, res.Err)
+			continue
+		}
+
+		succeeded++
+		fmt.Printf("  - %s (%s): %s in %s\n", res.RepoFullName, res.DockerfilePath, res.Status, res.Duration.Round(time.Millisecond))
+	}
+
+	fmt.Printf("\nDone: %d succeeded, %d failed (out of %d queued).\n", succeeded, failed, len(jobs))
+}
@@ -2,44 +2,126 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"gh-repo-research-api/internal/database"
+	"gh-repo-research-api/internal/dockerfile"
 	"gh-repo-research-api/internal/github"
 
+	"github.com/cheggaaa/pb/v3"
 	"github.com/google/uuid"
 )
 
+// boundedTemplate mirrors the default pb.Full template but adds a
+// repos/sec rate, matching the output operators already expect from
+// similar long-running scrape tools.
+const boundedTemplate = `{{ string . "prefix" }}{{ counters . }} {{ bar . }} {{ percent . }} {{ speed . "%s repos/s" }} {{ rtime . "ETA %s"}}{{ string . "suffix" }}`
+
+// unboundedTemplate is used when --max=0: we don't know the total, so
+// show a spinner with the running count and rate instead of a bar.
+const unboundedTemplate = `{{ string . "prefix" }}{{ spinner . }} {{ counters . }} {{ speed . "%s repos/s" }}{{ string . "suffix" }}`
+
+// newProgressBar builds a bar fed by totalFetched vs. maxRepos, or an
+// unbounded spinner when maxRepos is 0. Passing --no-progress or
+// --silent yields a bar that no-ops on every call.
+func newProgressBar(maxRepos int, disabled bool) *pb.ProgressBar {
+	if disabled {
+		bar := pb.New(0)
+		bar.SetWriter(io.Discard)
+		return bar
+	}
+
+	if maxRepos > 0 {
+		return pb.ProgressBarTemplate(boundedTemplate).New(maxRepos)
+	}
+	return pb.ProgressBarTemplate(unboundedTemplate).New(0)
+}
+
+// abortSignal returns a channel that fires once on SIGINT/SIGTERM. The
+// main loop checks it after each page's Dockerfile scan completes, so a
+// Ctrl-C never lands mid-page: the current page finishes, state is
+// flushed, and the process exits with a resume hint.
+func abortSignal() chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	return ch
+}
+
+// saveDockerfile parses a fetched Dockerfile's content and persists the
+// structured result, keyed to its repository by repoID.
+func saveDockerfile(db database.Store, repoID int, df github.DockerfileRecord) error {
+	record := dockerfile.Parse(df.Content)
+
+	rawInstructions, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode instructions: %w", err)
+	}
+
+	stageCount := len(record.Stages)
+	if stageCount == 0 {
+		stageCount = 1
+	}
+
+	var tag *string
+	if record.BaseImageTag != "" {
+		tag = &record.BaseImageTag
+	}
+
+	dbDockerfile := database.Dockerfile{
+		RepositoryID:    repoID,
+		Path:            df.Path,
+		BaseImage:       record.BaseImage,
+		Tag:             tag,
+		StageCount:      stageCount,
+		IsMultistage:    record.IsMultistage,
+		ExposesRoot:     record.User == "" || record.User == "root" || record.User == "0",
+		RawInstructions: rawInstructions,
+	}
+
+	return db.InsertDockerfile(dbDockerfile)
+}
+
 func main() {
 	var (
-		sessionID  = flag.String("session", "", "Session ID to resume (if empty, creates new session)")
-		query      = flag.String("query", "docker sort:stars-desc in:readme", "GitHub search query")
-		maxRepos   = flag.Int("max", 0, "Maximum number of repositories to fetch (0 = unlimited)")
-		listStates = flag.Bool("list", false, "List all saved search states")
-		deleteID   = flag.String("delete", "", "Delete search state with specified session ID")
+		sessionID    = flag.String("session", "", "Session ID to resume (if empty, creates new session)")
+		query        = flag.String("query", "docker sort:stars-desc in:readme", "GitHub search query")
+		maxRepos     = flag.Int("max", 0, "Maximum number of repositories to fetch (0 = unlimited)")
+		listStates   = flag.Bool("list", false, "List all saved search states")
+		deleteID     = flag.String("delete", "", "Delete search state with specified session ID")
+		noProgress   = flag.Bool("no-progress", false, "Disable the progress bar")
+		silent       = flag.Bool("silent", false, "Suppress per-repository output and the progress bar")
+		storeDriver  = flag.String("store", "postgres", "Storage backend: postgres or sqlite")
+		databaseURL  = flag.String("database-url", "", "Postgres connection string (or set DATABASE_URL)")
+		sqlitePath   = flag.String("sqlite-path", "", "SQLite database file path (or set SQLITE_PATH)")
+		concurrency  = flag.Int("concurrency", 8, "Number of repositories to scan for Dockerfiles concurrently")
+		maxTreeDepth = flag.Int("max-tree-depth", 4, "Maximum directory depth to search for Dockerfiles in a repository")
 	)
 	flag.Parse()
 
 	client := github.NewClient(os.Getenv("GITHUB_TOKEN"))
+	client.MaxTreeDepth = *maxTreeDepth
 
-	// Initialize database connection
-	db, err := database.NewConnection()
+	// Initialize the storage backend
+	dsn := *databaseURL
+	if *storeDriver == "sqlite" {
+		dsn = *sqlitePath
+	}
+	db, err := database.NewStore(*storeDriver, dsn)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to open %s store: %v", *storeDriver, err)
 	}
 	defer db.Close()
 
-	// Create tables
-	if err := db.CreateRepositoriesTable(); err != nil {
-		log.Fatalf("Failed to create repositories table: %v", err)
-	}
-	if err := db.CreateSearchStatesTable(); err != nil {
-		log.Fatalf("Failed to create search_states table: %v", err)
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
 	// Handle list command
@@ -102,7 +184,7 @@ func main() {
 		}
 
 		if state.IsCompleted {
-			fmt.Printf("Search session '%s' is already completed (%d repositories fetched).\n", 
+			fmt.Printf("Search session '%s' is already completed (%d repositories fetched).\n",
 				*sessionID, state.TotalFetched)
 			return
 		}
@@ -146,12 +228,22 @@ func main() {
 		fmt.Println()
 	}
 
+	sigCh := abortSignal()
+	aborting := false
+
+	bar := newProgressBar(*maxRepos, *noProgress || *silent)
+	bar.Set(pb.Bytes, false)
+	bar.SetCurrent(int64(totalFetched))
+	bar.Start()
+
 	pageCount := 0
+pageLoop:
 	for {
 		result, err := client.GetNextRepositories(ctx, currentCursor)
 		if err != nil {
+			bar.Finish()
 			log.Printf("Failed to search repositories: %v", err)
-			
+
 			// Save current state before exit
 			state := database.SearchState{
 				SessionID:     currentSessionID,
@@ -163,25 +255,31 @@ func main() {
 			if saveErr := db.SaveSearchState(state); saveErr != nil {
 				log.Printf("Failed to save search state: %v", saveErr)
 			}
-			
+
 			log.Fatalf("Search failed. State saved. You can resume with: --session=%s", currentSessionID)
 		}
 
 		pageCount++
-		fmt.Printf("Page %d: Found %d repositories\n", pageCount, len(result.Repositories))
-
-		for _, repo := range result.Repositories {
-			parts := strings.Split(repo.FullName, "/")
-			if len(parts) != 2 {
-				continue
-			}
-			owner, name := parts[0], parts[1]
+		if !*silent {
+			fmt.Printf("Page %d: Found %d repositories\n", pageCount, len(result.Repositories))
+		}
 
-			hasDockerfile, err := client.HasDockerfile(ctx, owner, name)
-			if err != nil {
-				fmt.Printf("Error checking Dockerfile for %s: %v\n", repo.FullName, err)
-				hasDockerfile = false
+		// Scan every repository on this page for Dockerfiles concurrently.
+		// The results channel only closes once all of them have been
+		// processed, so the cursor below never advances past a
+		// partially-persisted page - a crash mid-page means at-most-once
+		// duplicate work on resume, safe under the upsert in
+		// InsertRepository, rather than dropped rows.
+		for res := range client.ScanDockerfiles(ctx, result.Repositories, *concurrency) {
+			repo := res.Repository
+			dockerfiles := res.Dockerfiles
+			if res.Err != nil {
+				if !*silent {
+					fmt.Printf("Error fetching Dockerfiles for %s: %v\n", repo.FullName, res.Err)
+				}
+				dockerfiles = nil
 			}
+			hasDockerfile := len(dockerfiles) > 0
 
 			// Convert GitHub repo to database repo
 			dbRepo := database.Repository{
@@ -197,24 +295,44 @@ func main() {
 			}
 
 			// Save to database
-			if err := db.InsertRepository(dbRepo); err != nil {
-				fmt.Printf("Error saving repository %s: %v\n", repo.FullName, err)
+			repoID, err := db.InsertRepository(dbRepo)
+			if err != nil {
+				if !*silent {
+					fmt.Printf("Error saving repository %s: %v\n", repo.FullName, err)
+				}
 				continue
 			}
 
+			for _, df := range dockerfiles {
+				if err := saveDockerfile(db, repoID, df); err != nil && !*silent {
+					fmt.Printf("Error saving Dockerfile %s for %s: %v\n", df.Path, repo.FullName, err)
+				}
+			}
+
 			totalFetched++
+			bar.Increment()
 
-			status := ""
-			if hasDockerfile {
-				status = " [HAS DOCKERFILE]"
-			}
-			
-			language := "Unknown"
-			if repo.PrimaryLanguage != nil {
-				language = repo.PrimaryLanguage.Name
+			if !*silent {
+				status := ""
+				if hasDockerfile {
+					status = " [HAS DOCKERFILE]"
+				}
+
+				language := "Unknown"
+				if repo.PrimaryLanguage != nil {
+					language = repo.PrimaryLanguage.Name
+				}
+
+				fmt.Printf("  - %s (%d stars, %s)%s - SAVED\n", repo.FullName, repo.StargazerCount, language, status)
 			}
+		}
 
-			fmt.Printf("  - %s (%d stars, %s)%s - SAVED\n", repo.FullName, repo.StargazerCount, language, status)
+		// Only check for an abort once the whole page has been persisted
+		// (see the comment above the scan loop).
+		select {
+		case <-sigCh:
+			aborting = true
+		default:
 		}
 
 		// Update cursor
@@ -222,42 +340,54 @@ func main() {
 			currentCursor = *result.PageInfo.EndCursor
 		}
 
-		// Save current state after each page
+		// Save current state after each page (or after an aborted page)
 		state := database.SearchState{
 			SessionID:     currentSessionID,
 			Query:         *query,
 			CurrentCursor: &currentCursor,
 			TotalFetched:  totalFetched,
-			IsCompleted:   !result.PageInfo.HasNextPage,
+			IsCompleted:   !result.PageInfo.HasNextPage && !aborting,
 		}
 
 		if err := db.SaveSearchState(state); err != nil {
 			log.Printf("Failed to save search state: %v", err)
 		}
 
-		fmt.Printf("Progress: %d repositories fetched (session: %s)\n\n", totalFetched, currentSessionID)
-		
+		if aborting {
+			bar.Finish()
+			fmt.Println("\nAborting... current page finished, state saved.")
+			fmt.Printf("💡 Resume with: --session=%s\n", currentSessionID)
+			break pageLoop
+		}
+
+		if !*silent {
+			fmt.Printf("Progress: %d repositories fetched (session: %s)\n\n", totalFetched, currentSessionID)
+		}
+
 		// Check if we should stop
 		if !result.PageInfo.HasNextPage {
+			bar.Finish()
 			fmt.Println("🎉 Collection completed! All repositories have been fetched.")
-			break
+			break pageLoop
 		}
 
 		if result.PageInfo.EndCursor == nil {
+			bar.Finish()
 			fmt.Println("⚠️  No more pages available (cursor is nil).")
-			break
+			break pageLoop
 		}
 
 		// Check max limit
 		if *maxRepos > 0 && totalFetched >= *maxRepos {
+			bar.Finish()
 			fmt.Printf("🛑 Reached maximum limit of %d repositories.\n", *maxRepos)
-			
+
 			// Mark as completed since we reached the user-defined limit
 			state.IsCompleted = true
 			if err := db.SaveSearchState(state); err != nil {
 				log.Printf("Failed to save final search state: %v", err)
 			}
-			break
+			break pageLoop
 		}
 
 		// Add a small delay to be respectful to the API
@@ -265,9 +395,9 @@ func main() {
 	}
 
 	fmt.Printf("\n✅ Session %s finished. Total repositories collected: %d\n", currentSessionID, totalFetched)
-	
+
 	// Show how to resume if interrupted
-	if !(*maxRepos > 0 && totalFetched >= *maxRepos) {
+	if aborting || !(*maxRepos > 0 && totalFetched >= *maxRepos) {
 		fmt.Printf("💡 To resume this search later, use: --session=%s\n", currentSessionID)
 		fmt.Printf("💡 To list all sessions, use: --list\n")
 		fmt.Printf("💡 To delete this session, use: --delete=%s\n", currentSessionID)
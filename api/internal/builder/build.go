@@ -0,0 +1,254 @@
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gh-repo-research-api/internal/dockerfile"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Build statuses recorded in build_results.status.
+const (
+	StatusSuccess   = "success"
+	StatusFailure   = "failure"
+	StatusTimeout   = "timeout"
+	StatusValidated = "validated" // --dry-run: the Dockerfile parsed, nothing was built
+)
+
+// Job is one Dockerfile queued for a build attempt.
+type Job struct {
+	RepositoryID   int
+	DockerfileID   int
+	RepoURL        string
+	RepoFullName   string
+	DockerfilePath string
+}
+
+// Result is the outcome of attempting Job.
+type Result struct {
+	Job
+	Status     string
+	ImageID    string
+	SizeBytes  int64
+	LayerCount int
+	Duration   time.Duration
+	Err        error
+}
+
+// Run builds every job concurrently across a bounded pool of
+// concurrency workers, giving each build up to perBuildTimeout before
+// it's abandoned as a timeout. Modeled on the Client.ScanDockerfiles
+// worker pool: results stream back on the returned channel in
+// completion order and the channel closes once every job has been
+// attempted.
+func (b *B) Run(ctx context.Context, jobs []Job, concurrency int, perBuildTimeout time.Duration) <-chan Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobCh := make(chan Job)
+	results := make(chan Result)
+
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for job := range jobCh {
+				results <- b.buildOne(ctx, job, perBuildTimeout)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// buildOne clones job's repository, then either validates its
+// Dockerfile (DryRun) or builds it with a per-build timeout. The clone
+// is always removed before returning, win or lose.
+func (b *B) buildOne(ctx context.Context, job Job, timeout time.Duration) Result {
+	start := time.Now()
+
+	dir, err := cloneRepo(job.RepoURL)
+	if err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: err, Duration: time.Since(start)}
+	}
+	defer os.RemoveAll(dir)
+
+	content, err := os.ReadFile(filepath.Join(dir, job.DockerfilePath))
+	if err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: fmt.Errorf("failed to read %s: %w", job.DockerfilePath, err), Duration: time.Since(start)}
+	}
+
+	if b.DryRun {
+		record := dockerfile.Parse(string(content))
+		if record.BaseImage == "" {
+			return Result{Job: job, Status: StatusFailure, Err: fmt.Errorf("%s has no FROM instruction", job.DockerfilePath), Duration: time.Since(start)}
+		}
+		return Result{Job: job, Status: StatusValidated, Duration: time.Since(start)}
+	}
+
+	return b.dockerBuild(ctx, job, dir, timeout, start)
+}
+
+// dockerBuild tars dir as the build context and hands it to the Docker
+// daemon, streaming the build log to the per-repo log file and
+// inspecting the resulting image for size and layer count.
+func (b *B) dockerBuild(ctx context.Context, job Job, dir string, timeout time.Duration, start time.Time) Result {
+	buildCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	archive, err := archiveContext(dir)
+	if err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: err, Duration: time.Since(start)}
+	}
+
+	logw, err := b.logWriter(job.RepoFullName)
+	if err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: err, Duration: time.Since(start)}
+	}
+	defer logw.Close()
+
+	tag := imageTag(job.RepoFullName, job.DockerfilePath)
+	resp, err := b.docker.ImageBuild(buildCtx, archive, types.ImageBuildOptions{
+		Dockerfile:  job.DockerfilePath,
+		Tags:        []string{tag},
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		status := StatusFailure
+		if errors.Is(buildCtx.Err(), context.DeadlineExceeded) {
+			status = StatusTimeout
+		}
+		return Result{Job: job, Status: status, Err: err, Duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(logw, resp.Body); err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: fmt.Errorf("failed to stream build log: %w", err), Duration: time.Since(start)}
+	}
+
+	inspect, _, err := b.docker.ImageInspectWithRaw(ctx, tag)
+	if err != nil {
+		return Result{Job: job, Status: StatusFailure, Err: fmt.Errorf("failed to inspect built image: %w", err), Duration: time.Since(start)}
+	}
+	b.trackImage(inspect.ID)
+
+	return Result{
+		Job:        job,
+		Status:     StatusSuccess,
+		ImageID:    inspect.ID,
+		SizeBytes:  inspect.Size,
+		LayerCount: len(inspect.RootFS.Layers),
+		Duration:   time.Since(start),
+	}
+}
+
+// archiveContext tars dir into an in-memory build context, skipping the
+// .git directory cloneRepo leaves behind (the daemon doesn't need repo
+// history, only the working tree).
+func archiveContext(dir string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if parts := strings.Split(rel, string(filepath.Separator)); parts[0] == ".git" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(tw, f); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("failed to archive build context: %w", walkErr)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close build context archive: %w", err)
+	}
+
+	return &buf, nil
+}
+
+// imageTag derives a Docker-legal local tag from a repository name and
+// Dockerfile path, so multiple Dockerfiles in the same repo don't
+// collide on the same tag.
+func imageTag(repoFullName, dockerfilePath string) string {
+	return fmt.Sprintf("gh-repo-research-build/%s-%s:latest", sanitizeTagPart(repoFullName), sanitizeTagPart(dockerfilePath))
+}
+
+func sanitizeTagPart(s string) string {
+	s = strings.ToLower(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+// Package builder actually builds the Dockerfiles the collector found,
+// to evaluate reproducibility, image size, and (eventually)
+// vulnerabilities.
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// B holds the state for one build run: the Docker client, where logs
+// go, and every image it has spawned so Abort can clean them up on
+// SIGINT. Modeled on the context struct Drone's builder threads through
+// a build - one long-lived handle instead of passing the client and
+// bookkeeping around separately.
+type B struct {
+	docker  *client.Client
+	logsDir string
+	DryRun  bool
+
+	mu       sync.Mutex
+	imageIDs []string
+}
+
+// New creates a B streaming per-repository build logs to files under
+// logsDir (created if it doesn't exist). Unless dryRun is set, it also
+// dials the Docker client configured from the environment (DOCKER_HOST,
+// etc.) - dry runs only parse Dockerfiles, so they never need a daemon
+// to be reachable.
+func New(logsDir string, dryRun bool) (*B, error) {
+	b := &B{logsDir: logsDir, DryRun: dryRun}
+
+	if !dryRun {
+		docker, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create docker client: %w", err)
+		}
+		b.docker = docker
+	}
+
+	if logsDir != "" {
+		if err := os.MkdirAll(logsDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create logs dir %q: %w", logsDir, err)
+		}
+	}
+
+	return b, nil
+}
+
+func (b *B) trackImage(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.imageIDs = append(b.imageIDs, id)
+}
+
+// Abort force-removes every image this B has spawned so far. It's
+// best-effort: one failed removal doesn't stop the rest.
+func (b *B) Abort(ctx context.Context) error {
+	b.mu.Lock()
+	imageIDs := append([]string(nil), b.imageIDs...)
+	b.mu.Unlock()
+
+	var errs []error
+	for _, id := range imageIDs {
+		if _, err := b.docker.ImageRemove(ctx, id, image.RemoveOptions{Force: true}); err != nil {
+			errs = append(errs, fmt.Errorf("remove image %s: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// logWriter opens (creating) the per-repo log file under logsDir, or
+// discards output if logsDir is empty.
+func (b *B) logWriter(repoFullName string) (io.WriteCloser, error) {
+	if b.logsDir == "" {
+		return nopWriteCloser{io.Discard}, nil
+	}
+
+	name := strings.ReplaceAll(repoFullName, "/", "_") + ".log"
+	f, err := os.Create(filepath.Join(b.logsDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file for %s: %w", repoFullName, err)
+	}
+	return f, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,28 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// cloneRepo performs a shallow clone of url into a fresh temp
+// directory and returns its path. Callers are responsible for removing
+// it once they're done with it.
+func cloneRepo(url string) (string, error) {
+	dir, err := os.MkdirTemp("", "gh-repo-research-build-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create tempdir: %w", err)
+	}
+
+	if _, err := git.PlainClone(dir, false, &git.CloneOptions{
+		URL:   url,
+		Depth: 1,
+	}); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+
+	return dir, nil
+}
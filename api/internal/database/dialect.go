@@ -0,0 +1,390 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// dialect captures the handful of ways Postgres and SQLite queries
+// differ in this package: parameter placeholders, the EXCLUDED/excluded
+// pseudo-table upsert syntax uses, and the boolean literal used in a
+// WHERE clause. Everything else (column lists, scan logic) is identical
+// between backends and lives once in this file; postgres.go and
+// sqlite.go are left with only their CREATE TABLE statements, which
+// genuinely differ in column types (SERIAL vs AUTOINCREMENT, and so on).
+type dialect struct {
+	placeholder func(pos int) string // pos is 1-indexed
+	excluded    func(col string) string
+	trueLiteral string
+}
+
+var postgresDialect = dialect{
+	placeholder: func(pos int) string { return fmt.Sprintf("$%d", pos) },
+	excluded:    func(col string) string { return "EXCLUDED." + col },
+	trueLiteral: "TRUE",
+}
+
+var sqliteDialect = dialect{
+	placeholder: func(int) string { return "?" },
+	excluded:    func(col string) string { return "excluded." + col },
+	trueLiteral: "1",
+}
+
+// placeholders renders n sequential parameter placeholders ("$1, $2, $3"
+// or "?, ?, ?") for a VALUES (...) clause.
+func placeholders(d dialect, n int) string {
+	ps := make([]string, n)
+	for i := range ps {
+		ps[i] = d.placeholder(i + 1)
+	}
+	return strings.Join(ps, ", ")
+}
+
+// setExcluded renders the "col = EXCLUDED.col" (or excluded.col) list for
+// an ON CONFLICT ... DO UPDATE SET clause.
+func setExcluded(d dialect, cols ...string) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = fmt.Sprintf("%s = %s", c, d.excluded(c))
+	}
+	return strings.Join(parts, ",\n\t\t\t")
+}
+
+// appliedMigrations returns the set of migration names already recorded
+// in schema_migrations.
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// recordMigration marks name as applied. It's safe to call twice for the
+// same name - ON CONFLICT DO NOTHING makes this idempotent like the
+// CREATE TABLE IF NOT EXISTS migrations themselves.
+func recordMigration(db *sql.DB, d dialect, name string) error {
+	query := fmt.Sprintf(`INSERT INTO schema_migrations (name) VALUES (%s) ON CONFLICT (name) DO NOTHING`, d.placeholder(1))
+	_, err := db.Exec(query, name)
+	return err
+}
+
+// insertRepository upserts a repository by URL and returns its id, so
+// callers can attach related rows (e.g. dockerfiles) via foreign key.
+func insertRepository(db *sql.DB, d dialect, repo Repository) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO repositories (url, name_with_owner, stargazer_count, primary_language, has_dockerfile)
+		VALUES (%s)
+		ON CONFLICT (url) DO UPDATE SET
+			%s,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, placeholders(d, 5), setExcluded(d, "name_with_owner", "stargazer_count", "primary_language", "has_dockerfile"))
+
+	var id int
+	err := db.QueryRow(query, repo.URL, repo.NameWithOwner, repo.StargazerCount, repo.PrimaryLanguage, repo.HasDockerfile).Scan(&id)
+	return id, err
+}
+
+func scanRepositories(rows *sql.Rows) ([]Repository, error) {
+	var repositories []Repository
+	for rows.Next() {
+		var repo Repository
+		var primaryLanguage sql.NullString
+
+		err := rows.Scan(
+			&repo.ID,
+			&repo.URL,
+			&repo.NameWithOwner,
+			&repo.StargazerCount,
+			&primaryLanguage,
+			&repo.HasDockerfile,
+			&repo.CreatedAt,
+			&repo.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if primaryLanguage.Valid {
+			repo.PrimaryLanguage = &primaryLanguage.String
+		}
+
+		repositories = append(repositories, repo)
+	}
+
+	return repositories, rows.Err()
+}
+
+func getRepositories(db *sql.DB, d dialect, limit, offset int) ([]Repository, error) {
+	query := fmt.Sprintf(`
+		SELECT id, url, name_with_owner, stargazer_count, primary_language, has_dockerfile, created_at, updated_at
+		FROM repositories
+		ORDER BY stargazer_count DESC
+		LIMIT %s OFFSET %s
+	`, d.placeholder(1), d.placeholder(2))
+
+	rows, err := db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRepositories(rows)
+}
+
+// getRepositoriesWithDockerfile is getRepositories narrowed to
+// repositories flagged has_dockerfile, for callers (like the builder)
+// that only care about repos worth cloning.
+func getRepositoriesWithDockerfile(db *sql.DB, d dialect, limit, offset int) ([]Repository, error) {
+	query := fmt.Sprintf(`
+		SELECT id, url, name_with_owner, stargazer_count, primary_language, has_dockerfile, created_at, updated_at
+		FROM repositories
+		WHERE has_dockerfile = %s
+		ORDER BY stargazer_count DESC
+		LIMIT %s OFFSET %s
+	`, d.trueLiteral, d.placeholder(1), d.placeholder(2))
+
+	rows, err := db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRepositories(rows)
+}
+
+// insertDockerfile upserts a parsed Dockerfile, keyed by (repository_id, path).
+func insertDockerfile(db *sql.DB, d dialect, rec Dockerfile) error {
+	query := fmt.Sprintf(`
+		INSERT INTO dockerfiles (repository_id, path, base_image, tag, stage_count, is_multistage, exposes_root, raw_instructions)
+		VALUES (%s)
+		ON CONFLICT (repository_id, path) DO UPDATE SET
+			%s,
+			updated_at = CURRENT_TIMESTAMP
+	`, placeholders(d, 8), setExcluded(d, "base_image", "tag", "stage_count", "is_multistage", "exposes_root", "raw_instructions"))
+
+	_, err := db.Exec(query, rec.RepositoryID, rec.Path, rec.BaseImage, rec.Tag, rec.StageCount, rec.IsMultistage, rec.ExposesRoot, rec.RawInstructions)
+	return err
+}
+
+func getDockerfilesByRepository(db *sql.DB, d dialect, repositoryID int) ([]Dockerfile, error) {
+	query := fmt.Sprintf(`
+		SELECT id, repository_id, path, base_image, tag, stage_count, is_multistage, exposes_root, raw_instructions, created_at, updated_at
+		FROM dockerfiles
+		WHERE repository_id = %s
+		ORDER BY path
+	`, d.placeholder(1))
+
+	rows, err := db.Query(query, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dockerfiles []Dockerfile
+	for rows.Next() {
+		var rec Dockerfile
+		var tag sql.NullString
+
+		err := rows.Scan(
+			&rec.ID,
+			&rec.RepositoryID,
+			&rec.Path,
+			&rec.BaseImage,
+			&tag,
+			&rec.StageCount,
+			&rec.IsMultistage,
+			&rec.ExposesRoot,
+			&rec.RawInstructions,
+			&rec.CreatedAt,
+			&rec.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag.Valid {
+			rec.Tag = &tag.String
+		}
+
+		dockerfiles = append(dockerfiles, rec)
+	}
+
+	return dockerfiles, rows.Err()
+}
+
+// insertBuildResult records the outcome of one `docker build` attempt.
+// Unlike repositories and dockerfiles, build results are append-only -
+// a repo rebuilt after a Dockerfile change gets a new row rather than
+// an update, so history of past attempts is preserved.
+func insertBuildResult(db *sql.DB, d dialect, r BuildResult) error {
+	query := fmt.Sprintf(`
+		INSERT INTO build_results (repository_id, dockerfile_id, status, image_size_bytes, layer_count, duration_ms, error_message)
+		VALUES (%s)
+	`, placeholders(d, 7))
+
+	_, err := db.Exec(query, r.RepositoryID, r.DockerfileID, r.Status, r.ImageSizeBytes, r.LayerCount, r.DurationMs, r.ErrorMessage)
+	return err
+}
+
+func getBuildResultsByRepository(db *sql.DB, d dialect, repositoryID int) ([]BuildResult, error) {
+	query := fmt.Sprintf(`
+		SELECT id, repository_id, dockerfile_id, status, image_size_bytes, layer_count, duration_ms, error_message, created_at
+		FROM build_results
+		WHERE repository_id = %s
+		ORDER BY created_at DESC
+	`, d.placeholder(1))
+
+	rows, err := db.Query(query, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []BuildResult
+	for rows.Next() {
+		var r BuildResult
+		var imageSizeBytes sql.NullInt64
+		var layerCount sql.NullInt64
+		var errorMessage sql.NullString
+
+		err := rows.Scan(
+			&r.ID,
+			&r.RepositoryID,
+			&r.DockerfileID,
+			&r.Status,
+			&imageSizeBytes,
+			&layerCount,
+			&r.DurationMs,
+			&errorMessage,
+			&r.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if imageSizeBytes.Valid {
+			r.ImageSizeBytes = &imageSizeBytes.Int64
+		}
+		if layerCount.Valid {
+			count := int(layerCount.Int64)
+			r.LayerCount = &count
+		}
+		if errorMessage.Valid {
+			r.ErrorMessage = &errorMessage.String
+		}
+
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+func saveSearchState(db *sql.DB, d dialect, state SearchState) error {
+	query := fmt.Sprintf(`
+		INSERT INTO search_states (session_id, query, current_cursor, total_fetched, is_completed)
+		VALUES (%s)
+		ON CONFLICT (session_id) DO UPDATE SET
+			%s,
+			updated_at = CURRENT_TIMESTAMP
+	`, placeholders(d, 5), setExcluded(d, "current_cursor", "total_fetched", "is_completed"))
+
+	_, err := db.Exec(query, state.SessionID, state.Query, state.CurrentCursor, state.TotalFetched, state.IsCompleted)
+	return err
+}
+
+func loadSearchState(db *sql.DB, d dialect, sessionID string) (*SearchState, error) {
+	query := fmt.Sprintf(`
+		SELECT id, session_id, query, current_cursor, total_fetched, is_completed, created_at, updated_at
+		FROM search_states
+		WHERE session_id = %s
+	`, d.placeholder(1))
+
+	row := db.QueryRow(query, sessionID)
+
+	var state SearchState
+	var currentCursor sql.NullString
+
+	err := row.Scan(
+		&state.ID,
+		&state.SessionID,
+		&state.Query,
+		&currentCursor,
+		&state.TotalFetched,
+		&state.IsCompleted,
+		&state.CreatedAt,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if currentCursor.Valid {
+		state.CurrentCursor = &currentCursor.String
+	}
+
+	return &state, nil
+}
+
+func deleteSearchState(db *sql.DB, d dialect, sessionID string) error {
+	query := fmt.Sprintf(`DELETE FROM search_states WHERE session_id = %s`, d.placeholder(1))
+	_, err := db.Exec(query, sessionID)
+	return err
+}
+
+func listSearchStates(db *sql.DB, d dialect) ([]SearchState, error) {
+	query := `
+		SELECT id, session_id, query, current_cursor, total_fetched, is_completed, created_at, updated_at
+		FROM search_states
+		ORDER BY updated_at DESC
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []SearchState
+	for rows.Next() {
+		var state SearchState
+		var currentCursor sql.NullString
+
+		err := rows.Scan(
+			&state.ID,
+			&state.SessionID,
+			&state.Query,
+			&currentCursor,
+			&state.TotalFetched,
+			&state.IsCompleted,
+			&state.CreatedAt,
+			&state.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if currentCursor.Valid {
+			state.CurrentCursor = &currentCursor.String
+		}
+
+		states = append(states, state)
+	}
+
+	return states, rows.Err()
+}
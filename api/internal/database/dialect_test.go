@@ -0,0 +1,93 @@
+package database
+
+import "testing"
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		d    dialect
+		n    int
+		want string
+	}{
+		{"postgres", postgresDialect, 3, "$1, $2, $3"},
+		{"sqlite", sqliteDialect, 3, "?, ?, ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placeholders(tt.d, tt.n); got != tt.want {
+				t.Errorf("placeholders(%s, %d) = %q, want %q", tt.name, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetExcluded(t *testing.T) {
+	tests := []struct {
+		name string
+		d    dialect
+		want string
+	}{
+		{"postgres", postgresDialect, "a = EXCLUDED.a,\n\t\t\tb = EXCLUDED.b"},
+		{"sqlite", sqliteDialect, "a = excluded.a,\n\t\t\tb = excluded.b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setExcluded(tt.d, "a", "b"); got != tt.want {
+				t.Errorf("setExcluded(%s) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSQLiteUpsertRoundTrip exercises the shared query helpers end to
+// end against the SQLite dialect (no external service required) to
+// confirm the generated SQL is actually valid, not just textually
+// plausible.
+func TestSQLiteUpsertRoundTrip(t *testing.T) {
+	store, err := NewSQLiteStore(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	if err := Migrate(store); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	lang := "Go"
+	id, err := store.InsertRepository(Repository{
+		URL:             "https://github.com/example/repo",
+		NameWithOwner:   "example/repo",
+		StargazerCount:  1,
+		PrimaryLanguage: &lang,
+		HasDockerfile:   true,
+	})
+	if err != nil {
+		t.Fatalf("InsertRepository: %v", err)
+	}
+
+	// Re-insert the same URL with a different star count to exercise the
+	// ON CONFLICT DO UPDATE path, not just the INSERT path.
+	if _, err := store.InsertRepository(Repository{
+		URL:             "https://github.com/example/repo",
+		NameWithOwner:   "example/repo",
+		StargazerCount:  42,
+		PrimaryLanguage: &lang,
+		HasDockerfile:   true,
+	}); err != nil {
+		t.Fatalf("InsertRepository (upsert): %v", err)
+	}
+
+	repos, err := store.GetRepositoriesWithDockerfile(10, 0)
+	if err != nil {
+		t.Fatalf("GetRepositoriesWithDockerfile: %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("got %d repositories, want 1", len(repos))
+	}
+	if repos[0].ID != id || repos[0].StargazerCount != 42 {
+		t.Errorf("got %+v, want id=%d stargazerCount=42", repos[0], id)
+	}
+}
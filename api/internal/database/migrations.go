@@ -0,0 +1,49 @@
+package database
+
+import "fmt"
+
+// Migration is one schema step applied to a Store, tracked by Name in
+// the schema_migrations table so Migrate only runs it once. Like Drone's
+// migrate scheme, Up only ever needs to move the schema forward - there
+// is no Down, since nothing in this pipeline has needed to roll one back.
+type Migration struct {
+	Name string
+	Up   func(Store) error
+}
+
+var migrations = []Migration{
+	{Name: "001_create_repositories", Up: func(s Store) error { return s.CreateRepositoriesTable() }},
+	{Name: "002_create_search_states", Up: func(s Store) error { return s.CreateSearchStatesTable() }},
+	{Name: "003_create_dockerfiles", Up: func(s Store) error { return s.CreateDockerfilesTable() }},
+	{Name: "004_create_build_results", Up: func(s Store) error { return s.CreateBuildResultsTable() }},
+}
+
+// Migrate runs every migration not yet recorded in schema_migrations
+// against store, in order, regardless of which backend it is. Past
+// migrations stay untouched once applied, so a later migration that
+// needs to ALTER an existing table (rather than just create a new one)
+// can rely on prior steps having already run exactly once.
+func Migrate(store Store) error {
+	if err := store.CreateSchemaMigrationsTable(); err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	applied, err := store.AppliedMigrations()
+	if err != nil {
+		return fmt.Errorf("loading applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.Name] {
+			continue
+		}
+		if err := m.Up(store); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+		if err := store.RecordMigration(m.Name); err != nil {
+			return fmt.Errorf("migration %s: recording applied state: %w", m.Name, err)
+		}
+	}
+
+	return nil
+}
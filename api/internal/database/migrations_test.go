@@ -0,0 +1,34 @@
+package database
+
+import "testing"
+
+func TestMigrateRecordsAppliedMigrations(t *testing.T) {
+	store, err := NewSQLiteStore(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	if err := Migrate(store); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	applied, err := store.AppliedMigrations()
+	if err != nil {
+		t.Fatalf("AppliedMigrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("got %d applied migrations, want %d", len(applied), len(migrations))
+	}
+	for _, m := range migrations {
+		if !applied[m.Name] {
+			t.Errorf("migration %s not recorded as applied", m.Name)
+		}
+	}
+
+	// Running Migrate again must be a no-op: every migration is already
+	// recorded, so none of the (idempotent) Up funcs need to re-run.
+	if err := Migrate(store); err != nil {
+		t.Fatalf("Migrate (second run): %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// Postgres is the original Store backend: research data lives in a
+// PostgreSQL database.
+type Postgres struct {
+	*sql.DB
+}
+
+// NewPostgresStore opens a connection using dsn, falling back to
+// DATABASE_URL from the environment when dsn is empty.
+func NewPostgresStore(dsn string) (*Postgres, error) {
+	if dsn == "" {
+		dsn = os.Getenv("DATABASE_URL")
+	}
+	if dsn == "" {
+		return nil, fmt.Errorf("no postgres connection string provided (set --database-url or DATABASE_URL)")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	return &Postgres{DB: db}, nil
+}
+
+func (p *Postgres) CreateRepositoriesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS repositories (
+			id SERIAL PRIMARY KEY,
+			url VARCHAR(255) UNIQUE NOT NULL,
+			name_with_owner VARCHAR(255) NOT NULL,
+			stargazer_count INTEGER NOT NULL,
+			primary_language VARCHAR(100),
+			has_dockerfile BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := p.Exec(query)
+	return err
+}
+
+func (p *Postgres) CreateSearchStatesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS search_states (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(255) UNIQUE NOT NULL,
+			query VARCHAR(500) NOT NULL,
+			current_cursor TEXT,
+			total_fetched INTEGER DEFAULT 0,
+			is_completed BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := p.Exec(query)
+	return err
+}
+
+func (p *Postgres) CreateDockerfilesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS dockerfiles (
+			id SERIAL PRIMARY KEY,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			path VARCHAR(500) NOT NULL,
+			base_image VARCHAR(255),
+			tag VARCHAR(255),
+			stage_count INTEGER NOT NULL DEFAULT 1,
+			is_multistage BOOLEAN DEFAULT FALSE,
+			exposes_root BOOLEAN DEFAULT FALSE,
+			raw_instructions JSONB,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (repository_id, path)
+		)
+	`
+	_, err := p.Exec(query)
+	return err
+}
+
+func (p *Postgres) CreateBuildResultsTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS build_results (
+			id SERIAL PRIMARY KEY,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			dockerfile_id INTEGER NOT NULL REFERENCES dockerfiles(id) ON DELETE CASCADE,
+			status VARCHAR(50) NOT NULL,
+			image_size_bytes BIGINT,
+			layer_count INTEGER,
+			duration_ms BIGINT NOT NULL,
+			error_message TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := p.Exec(query)
+	return err
+}
+
+func (p *Postgres) CreateSchemaMigrationsTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := p.Exec(query)
+	return err
+}
+
+func (p *Postgres) AppliedMigrations() (map[string]bool, error) {
+	return appliedMigrations(p.DB)
+}
+
+func (p *Postgres) RecordMigration(name string) error {
+	return recordMigration(p.DB, postgresDialect, name)
+}
+
+func (p *Postgres) InsertRepository(repo Repository) (int, error) {
+	return insertRepository(p.DB, postgresDialect, repo)
+}
+
+func (p *Postgres) GetRepositories(limit, offset int) ([]Repository, error) {
+	return getRepositories(p.DB, postgresDialect, limit, offset)
+}
+
+func (p *Postgres) GetRepositoriesWithDockerfile(limit, offset int) ([]Repository, error) {
+	return getRepositoriesWithDockerfile(p.DB, postgresDialect, limit, offset)
+}
+
+func (p *Postgres) InsertDockerfile(d Dockerfile) error {
+	return insertDockerfile(p.DB, postgresDialect, d)
+}
+
+func (p *Postgres) GetDockerfilesByRepository(repositoryID int) ([]Dockerfile, error) {
+	return getDockerfilesByRepository(p.DB, postgresDialect, repositoryID)
+}
+
+func (p *Postgres) InsertBuildResult(r BuildResult) error {
+	return insertBuildResult(p.DB, postgresDialect, r)
+}
+
+func (p *Postgres) GetBuildResultsByRepository(repositoryID int) ([]BuildResult, error) {
+	return getBuildResultsByRepository(p.DB, postgresDialect, repositoryID)
+}
+
+func (p *Postgres) SaveSearchState(state SearchState) error {
+	return saveSearchState(p.DB, postgresDialect, state)
+}
+
+func (p *Postgres) LoadSearchState(sessionID string) (*SearchState, error) {
+	return loadSearchState(p.DB, postgresDialect, sessionID)
+}
+
+func (p *Postgres) DeleteSearchState(sessionID string) error {
+	return deleteSearchState(p.DB, postgresDialect, sessionID)
+}
+
+func (p *Postgres) ListSearchStates() ([]SearchState, error) {
+	return listSearchStates(p.DB, postgresDialect)
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLite is a pure-Go (no cgo) Store backend, intended for local
+// research runs and portable datasets: the whole dataset lives in a
+// single file.
+type SQLite struct {
+	*sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the SQLite database at
+// path, falling back to SQLITE_PATH from the environment and then
+// "gh-repo-research.db" when path is empty.
+func NewSQLiteStore(path string) (*SQLite, error) {
+	if path == "" {
+		path = os.Getenv("SQLITE_PATH")
+	}
+	if path == "" {
+		path = "gh-repo-research.db"
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %q: %w", path, err)
+	}
+
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		return nil, fmt.Errorf("failed to enable sqlite foreign keys: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %q: %w", path, err)
+	}
+
+	return &SQLite{DB: db}, nil
+}
+
+func (s *SQLite) CreateRepositoriesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS repositories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT UNIQUE NOT NULL,
+			name_with_owner TEXT NOT NULL,
+			stargazer_count INTEGER NOT NULL,
+			primary_language TEXT,
+			has_dockerfile BOOLEAN DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := s.Exec(query)
+	return err
+}
+
+func (s *SQLite) CreateSearchStatesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS search_states (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT UNIQUE NOT NULL,
+			query TEXT NOT NULL,
+			current_cursor TEXT,
+			total_fetched INTEGER DEFAULT 0,
+			is_completed BOOLEAN DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := s.Exec(query)
+	return err
+}
+
+func (s *SQLite) CreateDockerfilesTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS dockerfiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			path TEXT NOT NULL,
+			base_image TEXT,
+			tag TEXT,
+			stage_count INTEGER NOT NULL DEFAULT 1,
+			is_multistage BOOLEAN DEFAULT 0,
+			exposes_root BOOLEAN DEFAULT 0,
+			raw_instructions TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (repository_id, path)
+		)
+	`
+	_, err := s.Exec(query)
+	return err
+}
+
+func (s *SQLite) CreateBuildResultsTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS build_results (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			dockerfile_id INTEGER NOT NULL REFERENCES dockerfiles(id) ON DELETE CASCADE,
+			status TEXT NOT NULL,
+			image_size_bytes INTEGER,
+			layer_count INTEGER,
+			duration_ms INTEGER NOT NULL,
+			error_message TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := s.Exec(query)
+	return err
+}
+
+func (s *SQLite) CreateSchemaMigrationsTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	_, err := s.Exec(query)
+	return err
+}
+
+func (s *SQLite) AppliedMigrations() (map[string]bool, error) {
+	return appliedMigrations(s.DB)
+}
+
+func (s *SQLite) RecordMigration(name string) error {
+	return recordMigration(s.DB, sqliteDialect, name)
+}
+
+func (s *SQLite) InsertRepository(repo Repository) (int, error) {
+	return insertRepository(s.DB, sqliteDialect, repo)
+}
+
+func (s *SQLite) GetRepositories(limit, offset int) ([]Repository, error) {
+	return getRepositories(s.DB, sqliteDialect, limit, offset)
+}
+
+func (s *SQLite) GetRepositoriesWithDockerfile(limit, offset int) ([]Repository, error) {
+	return getRepositoriesWithDockerfile(s.DB, sqliteDialect, limit, offset)
+}
+
+func (s *SQLite) InsertDockerfile(d Dockerfile) error {
+	return insertDockerfile(s.DB, sqliteDialect, d)
+}
+
+func (s *SQLite) GetDockerfilesByRepository(repositoryID int) ([]Dockerfile, error) {
+	return getDockerfilesByRepository(s.DB, sqliteDialect, repositoryID)
+}
+
+func (s *SQLite) InsertBuildResult(r BuildResult) error {
+	return insertBuildResult(s.DB, sqliteDialect, r)
+}
+
+func (s *SQLite) GetBuildResultsByRepository(repositoryID int) ([]BuildResult, error) {
+	return getBuildResultsByRepository(s.DB, sqliteDialect, repositoryID)
+}
+
+func (s *SQLite) SaveSearchState(state SearchState) error {
+	return saveSearchState(s.DB, sqliteDialect, state)
+}
+
+func (s *SQLite) LoadSearchState(sessionID string) (*SearchState, error) {
+	return loadSearchState(s.DB, sqliteDialect, sessionID)
+}
+
+func (s *SQLite) DeleteSearchState(sessionID string) error {
+	return deleteSearchState(s.DB, sqliteDialect, sessionID)
+}
+
+func (s *SQLite) ListSearchStates() ([]SearchState, error) {
+	return listSearchStates(s.DB, sqliteDialect)
+}
@@ -0,0 +1,110 @@
+// Package database holds the persistence layer for the collector: the
+// dialect-agnostic record types, the Store interface every backend
+// implements, and the migration runner that creates/evolves their
+// schemas.
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Repository struct {
+	ID              int       `json:"id"`
+	URL             string    `json:"url"`
+	NameWithOwner   string    `json:"nameWithOwner"`
+	StargazerCount  int       `json:"stargazerCount"`
+	PrimaryLanguage *string   `json:"primaryLanguage"`
+	HasDockerfile   bool      `json:"hasDockerfile"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type SearchState struct {
+	ID            int       `json:"id"`
+	SessionID     string    `json:"sessionId"`
+	Query         string    `json:"query"`
+	CurrentCursor *string   `json:"currentCursor"`
+	TotalFetched  int       `json:"totalFetched"`
+	IsCompleted   bool      `json:"isCompleted"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Dockerfile is a single parsed Dockerfile found in a repository's tree,
+// keyed to its repository by RepositoryID.
+type Dockerfile struct {
+	ID              int             `json:"id"`
+	RepositoryID    int             `json:"repositoryId"`
+	Path            string          `json:"path"`
+	BaseImage       string          `json:"baseImage"`
+	Tag             *string         `json:"tag"`
+	StageCount      int             `json:"stageCount"`
+	IsMultistage    bool            `json:"isMultistage"`
+	ExposesRoot     bool            `json:"exposesRoot"`
+	RawInstructions json.RawMessage `json:"rawInstructions"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+}
+
+// BuildResult is the outcome of attempting to `docker build` a single
+// Dockerfile found in a repository, keyed to its repository by
+// RepositoryID.
+type BuildResult struct {
+	ID             int       `json:"id"`
+	RepositoryID   int       `json:"repositoryId"`
+	DockerfileID   int       `json:"dockerfileId"`
+	Status         string    `json:"status"`
+	ImageSizeBytes *int64    `json:"imageSizeBytes"`
+	LayerCount     *int      `json:"layerCount"`
+	DurationMs     int64     `json:"durationMs"`
+	ErrorMessage   *string   `json:"errorMessage"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Store is the backend-agnostic persistence interface the collector
+// runs against. Postgres and SQLite each implement it with their own
+// schema dialect (placeholder style, upsert syntax, column types).
+type Store interface {
+	CreateRepositoriesTable() error
+	CreateSearchStatesTable() error
+	CreateDockerfilesTable() error
+	CreateBuildResultsTable() error
+	CreateSchemaMigrationsTable() error
+
+	AppliedMigrations() (map[string]bool, error)
+	RecordMigration(name string) error
+
+	InsertRepository(repo Repository) (int, error)
+	GetRepositories(limit, offset int) ([]Repository, error)
+	GetRepositoriesWithDockerfile(limit, offset int) ([]Repository, error)
+
+	InsertDockerfile(d Dockerfile) error
+	GetDockerfilesByRepository(repositoryID int) ([]Dockerfile, error)
+
+	InsertBuildResult(r BuildResult) error
+	GetBuildResultsByRepository(repositoryID int) ([]BuildResult, error)
+
+	SaveSearchState(state SearchState) error
+	LoadSearchState(sessionID string) (*SearchState, error)
+	ListSearchStates() ([]SearchState, error)
+	DeleteSearchState(sessionID string) error
+
+	Close() error
+}
+
+// NewStore opens a Store backed by the given driver ("postgres" or
+// "sqlite"). dsn is a Postgres connection string for "postgres" and a
+// database file path for "sqlite"; an empty dsn falls back to the
+// backend's own environment variable (DATABASE_URL / SQLITE_PATH).
+func NewStore(driver, dsn string) (Store, error) {
+	switch driver {
+	case "postgres", "":
+		return NewPostgresStore(dsn)
+	case "sqlite":
+		return NewSQLiteStore(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported store driver %q (want \"postgres\" or \"sqlite\")", driver)
+	}
+}
@@ -0,0 +1,167 @@
+// Package dockerfile parses raw Dockerfile text into the structured
+// fields the research pipeline persists (base image, stages, exposed
+// ports, and so on).
+package dockerfile
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Record is the structured result of parsing a single Dockerfile.
+type Record struct {
+	BaseImage       string   `json:"baseImage"`
+	BaseImageTag    string   `json:"baseImageTag"`
+	Stages          []string `json:"stages"`
+	IsMultistage    bool     `json:"isMultistage"`
+	ExposedPorts    []int    `json:"exposedPorts"`
+	User            string   `json:"user"`
+	HasHealthcheck  bool     `json:"hasHealthcheck"`
+	PackageManagers []string `json:"packageManagers"`
+	Entrypoint      string   `json:"entrypoint"`
+	Cmd             string   `json:"cmd"`
+}
+
+var (
+	fromRe   = regexp.MustCompile(`(?i)^FROM\s+(\S+)(?:\s+AS\s+(\S+))?`)
+	exposeRe = regexp.MustCompile(`(?i)^EXPOSE\s+(.+)`)
+	userRe   = regexp.MustCompile(`(?i)^USER\s+(\S+)`)
+	healthRe = regexp.MustCompile(`(?i)^HEALTHCHECK\b`)
+	runRe    = regexp.MustCompile(`(?i)^RUN\s+(.+)`)
+	entryRe  = regexp.MustCompile(`(?i)^ENTRYPOINT\s+(.+)`)
+	cmdRe    = regexp.MustCompile(`(?i)^CMD\s+(.+)`)
+)
+
+// knownPackageManagers lists the invocations we scan RUN instructions
+// for. Matching is whole-token rather than a full shell parse -
+// Dockerfile RUN lines are shell snippets, not a grammar worth building
+// out for this - but each name is still bounded so "apt-get" doesn't
+// also report as "apt", and "pip3" doesn't also report as "pip".
+var knownPackageManagers = []string{
+	"apt-get", "apt", "apk", "yum", "dnf", "pip3", "pip", "npm", "yarn", "pnpm", "gem", "cargo",
+}
+
+// packageManagerPattern matches name as a whole token: not preceded or
+// followed by another identifier character. Hyphens count as identifier
+// characters here (unlike \b's word-boundary rule) specifically so
+// "apt" doesn't match as a prefix of "apt-get".
+func packageManagerPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^a-zA-Z0-9_-])` + regexp.QuoteMeta(name) + `(?:[^a-zA-Z0-9_-]|$)`)
+}
+
+var packageManagerRes = func() map[string]*regexp.Regexp {
+	res := make(map[string]*regexp.Regexp, len(knownPackageManagers))
+	for _, pm := range knownPackageManagers {
+		res[pm] = packageManagerPattern(pm)
+	}
+	return res
+}()
+
+// Parse extracts a best-effort Record from raw Dockerfile text. It is
+// tolerant of unusual or unparsable lines - Dockerfiles vary widely in
+// style, so those are simply skipped rather than treated as errors.
+func Parse(content string) Record {
+	var record Record
+	seenPackageManagers := make(map[string]bool)
+
+	for _, rawLine := range strings.Split(joinContinuations(content), "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case fromRe.MatchString(line):
+			m := fromRe.FindStringSubmatch(line)
+			if record.BaseImage == "" {
+				record.BaseImage, record.BaseImageTag = splitImageRef(m[1])
+			}
+			if m[2] != "" {
+				record.Stages = append(record.Stages, m[2])
+			}
+
+		case exposeRe.MatchString(line):
+			m := exposeRe.FindStringSubmatch(line)
+			for _, field := range strings.Fields(m[1]) {
+				portStr, _, _ := strings.Cut(field, "/")
+				if port, err := strconv.Atoi(portStr); err == nil {
+					record.ExposedPorts = append(record.ExposedPorts, port)
+				}
+			}
+
+		case userRe.MatchString(line):
+			record.User = userRe.FindStringSubmatch(line)[1]
+
+		case healthRe.MatchString(line):
+			record.HasHealthcheck = true
+
+		case runRe.MatchString(line):
+			runCmd := runRe.FindStringSubmatch(line)[1]
+			for _, pm := range knownPackageManagers {
+				if packageManagerRes[pm].MatchString(runCmd) {
+					seenPackageManagers[pm] = true
+				}
+			}
+
+		case entryRe.MatchString(line):
+			record.Entrypoint = entryRe.FindStringSubmatch(line)[1]
+
+		case cmdRe.MatchString(line):
+			record.Cmd = cmdRe.FindStringSubmatch(line)[1]
+		}
+	}
+
+	record.IsMultistage = len(record.Stages) > 0
+	for pm := range seenPackageManagers {
+		record.PackageManagers = append(record.PackageManagers, pm)
+	}
+
+	return record
+}
+
+// joinContinuations folds backslash line-continuations into the
+// physical line that starts them, so a RUN (or any other instruction)
+// that wraps across several lines is matched as one logical line. A
+// trailing "\" is only treated as a continuation when it's the last
+// character before the newline, matching Dockerfile's own rule. Comment
+// lines never start or extend a continuation - a stray trailing "\" in
+// a "#" comment shouldn't swallow the instruction after it.
+func joinContinuations(content string) string {
+	lines := strings.Split(content, "\n")
+	joined := make([]string, 0, len(lines))
+	continuing := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		isComment := strings.HasPrefix(strings.TrimSpace(trimmed), "#")
+
+		if continuing && !isComment {
+			prev := joined[len(joined)-1]
+			joined[len(joined)-1] = strings.TrimSuffix(prev, "\\") + strings.TrimLeft(trimmed, " \t")
+		} else {
+			joined = append(joined, trimmed)
+		}
+
+		continuing = !isComment && strings.HasSuffix(trimmed, "\\")
+	}
+
+	return strings.Join(joined, "\n")
+}
+
+// splitImageRef splits a FROM reference such as "golang:1.21-alpine" or
+// "registry.example.com:5000/app@sha256:abcd" into base image and
+// tag/digest. It guards against registry host:port prefixes by only
+// treating a colon after the last slash as the tag separator.
+func splitImageRef(ref string) (image, tagOrDigest string) {
+	if idx := strings.Index(ref, "@"); idx != -1 {
+		return ref[:idx], ref[idx+1:]
+	}
+
+	slash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon != -1 && colon > slash {
+		return ref[:colon], ref[colon+1:]
+	}
+
+	return ref, ""
+}
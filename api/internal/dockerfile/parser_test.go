@@ -0,0 +1,159 @@
+package dockerfile
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Record
+	}{
+		{
+			name: "single stage",
+			content: `
+				FROM golang:1.21-alpine
+				EXPOSE 8080/tcp 9090
+				USER appuser
+				HEALTHCHECK CMD curl -f http://localhost/ || exit 1
+				ENTRYPOINT ["/app"]
+				CMD ["serve"]
+			`,
+			want: Record{
+				BaseImage:      "golang",
+				BaseImageTag:   "1.21-alpine",
+				ExposedPorts:   []int{8080, 9090},
+				User:           "appuser",
+				HasHealthcheck: true,
+				Entrypoint:     `["/app"]`,
+				Cmd:            `["serve"]`,
+			},
+		},
+		{
+			name: "multistage records named stages",
+			content: `
+				FROM golang:1.21 AS builder
+				FROM alpine:3.19
+			`,
+			want: Record{
+				BaseImage:    "golang",
+				BaseImageTag: "1.21",
+				Stages:       []string{"builder"},
+				IsMultistage: true,
+			},
+		},
+		{
+			name:    "registry host:port is not mistaken for a tag",
+			content: `FROM registry.example.com:5000/app@sha256:abcd`,
+			want: Record{
+				BaseImage:    "registry.example.com:5000/app",
+				BaseImageTag: "sha256:abcd",
+			},
+		},
+		{
+			name: "RUN line-continuation still matches package managers",
+			content: "FROM alpine:3.19\n" +
+				"RUN set -e && \\\n" +
+				"    apk add --no-cache git",
+			want: Record{
+				BaseImage:       "alpine",
+				BaseImageTag:    "3.19",
+				PackageManagers: []string{"apk"},
+			},
+		},
+		{
+			name:    "RUN without continuation still matches",
+			content: "FROM alpine:3.19\nRUN apt-get update && apt-get install -y curl",
+			want: Record{
+				BaseImage:       "alpine",
+				BaseImageTag:    "3.19",
+				PackageManagers: []string{"apt-get"},
+			},
+		},
+		{
+			name:    "apt-get is not also reported as apt",
+			content: "FROM debian:12\nRUN apt-get update && apt-get install -y curl",
+			want: Record{
+				BaseImage:       "debian",
+				BaseImageTag:    "12",
+				PackageManagers: []string{"apt-get"},
+			},
+		},
+		{
+			name:    "bare apt is still detected on its own",
+			content: "FROM debian:12\nRUN apt update && apt install -y curl",
+			want: Record{
+				BaseImage:       "debian",
+				BaseImageTag:    "12",
+				PackageManagers: []string{"apt"},
+			},
+		},
+		{
+			name:    "pip3 is not also reported as pip",
+			content: "FROM python:3.12\nRUN pip3 install requests",
+			want: Record{
+				BaseImage:       "python",
+				BaseImageTag:    "3.12",
+				PackageManagers: []string{"pip3"},
+			},
+		},
+		{
+			name:    "bare pip is still detected on its own",
+			content: "FROM python:3.12\nRUN pip install requests",
+			want: Record{
+				BaseImage:       "python",
+				BaseImageTag:    "3.12",
+				PackageManagers: []string{"pip"},
+			},
+		},
+		{
+			name: "a trailing backslash in a comment does not swallow the next line",
+			content: "FROM alpine:3.19\n" +
+				"# a trailing backslash in a comment \\\n" +
+				"RUN apk add --no-cache git",
+			want: Record{
+				BaseImage:       "alpine",
+				BaseImageTag:    "3.19",
+				PackageManagers: []string{"apk"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.content)
+			sort.Strings(got.PackageManagers)
+			sort.Strings(tt.want.PackageManagers)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantImage   string
+		wantTagOrID string
+	}{
+		{"golang:1.21-alpine", "golang", "1.21-alpine"},
+		{"alpine", "alpine", ""},
+		{"registry.example.com:5000/app", "registry.example.com:5000/app", ""},
+		{"registry.example.com:5000/app:v1", "registry.example.com:5000/app", "v1"},
+		{"app@sha256:abcd", "app", "sha256:abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			image, tagOrID := splitImageRef(tt.ref)
+			if image != tt.wantImage || tagOrID != tt.wantTagOrID {
+				t.Errorf("splitImageRef(%q) = (%q, %q), want (%q, %q)", tt.ref, image, tagOrID, tt.wantImage, tt.wantTagOrID)
+			}
+		})
+	}
+}
@@ -6,15 +6,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 )
 
 const GitHubGraphQLEndpoint = "https://api.github.com/graphql"
 
+// maxQueryAttempts caps retries for transient failures: HTTP 502/503
+// and GitHub's secondary rate-limit error.
+const maxQueryAttempts = 5
+
+// secondaryRateLimitMessage is the substring GitHub's GraphQL API uses
+// to flag a secondary (abuse-detection) rate limit, distinct from the
+// primary rateLimit budget tracked by RateLimiter.
+const secondaryRateLimitMessage = "You have exceeded a secondary rate limit"
+
 type Client struct {
-	httpClient *http.Client
-	token      string
+	httpClient  *http.Client
+	token       string
+	rateLimiter *RateLimiter
+
+	// MaxTreeDepth caps how many directory levels findDockerfilePaths
+	// will descend, bounding GraphQL round-trips for repositories with
+	// unusually deep trees. Set by NewClient to defaultMaxTreeDepth;
+	// callers that need to search deeper (or shallower) monorepos can
+	// override it directly.
+	MaxTreeDepth int
+
+	// endpoint and backoff are overridden by tests to point at an
+	// httptest.Server and skip real sleeps; production code always gets
+	// the zero-value defaults set by NewClient.
+	endpoint string
+	backoff  func(ctx context.Context, attempt int) error
 }
 
 func NewClient(token string) *Client {
@@ -22,7 +47,11 @@ func NewClient(token string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		token: token,
+		token:        token,
+		rateLimiter:  NewRateLimiter(defaultRateLimitThreshold),
+		MaxTreeDepth: defaultMaxTreeDepth,
+		endpoint:     GitHubGraphQLEndpoint,
+		backoff:      backoffSleep,
 	}
 }
 
@@ -31,15 +60,50 @@ type GraphQLRequest struct {
 	Variables map[string]interface{} `json:"variables,omitempty"`
 }
 
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
 type GraphQLResponse struct {
-	Data   interface{} `json:"data"`
-	Errors []struct {
-		Message string        `json:"message"`
-		Path    []interface{} `json:"path,omitempty"`
-	} `json:"errors,omitempty"`
+	Data   interface{}    `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
 }
 
+// Query runs a GraphQL query, waiting on the shared RateLimiter first
+// and retrying transient failures (HTTP 502/503, GitHub's secondary
+// rate limit) with exponential backoff and jitter, up to
+// maxQueryAttempts.
 func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	if err := c.rateLimiter.WaitIfNeeded(ctx); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxQueryAttempts; attempt++ {
+		if attempt > 0 {
+			if err := c.backoff(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		retryable, err := c.doQuery(ctx, query, variables, result)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if !retryable {
+			return err
+		}
+	}
+
+	return fmt.Errorf("query failed after %d attempts: %w", maxQueryAttempts, lastErr)
+}
+
+// doQuery performs a single request/response round trip. The bool
+// return reports whether the error (if any) is worth retrying.
+func (c *Client) doQuery(ctx context.Context, query string, variables map[string]interface{}, result interface{}) (bool, error) {
 	req := GraphQLRequest{
 		Query:     query,
 		Variables: variables,
@@ -47,12 +111,12 @@ func (c *Client) Query(ctx context.Context, query string, variables map[string]i
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return false, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", GitHubGraphQLEndpoint, bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Authorization", "Bearer "+c.token)
@@ -61,36 +125,85 @@ func (c *Client) Query(ctx context.Context, query string, variables map[string]i
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return true, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return false, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable {
+		return true, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return false, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
 	}
 
 	var gqlResp GraphQLResponse
 	if err := json.Unmarshal(body, &gqlResp); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+		return false, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return fmt.Errorf("GraphQL errors: %+v", gqlResp.Errors)
+		return isSecondaryRateLimitError(gqlResp.Errors), fmt.Errorf("GraphQL errors: %+v", gqlResp.Errors)
 	}
 
 	dataBytes, err := json.Marshal(gqlResp.Data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		return false, fmt.Errorf("failed to marshal data: %w", err)
 	}
 
+	c.recordRateLimit(dataBytes)
+
 	if err := json.Unmarshal(dataBytes, result); err != nil {
-		return fmt.Errorf("failed to unmarshal data into result: %w", err)
+		return false, fmt.Errorf("failed to unmarshal data into result: %w", err)
+	}
+
+	return false, nil
+}
+
+// recordRateLimit pulls the rateLimit { remaining, resetAt } block out
+// of a response's data, if the query requested one, and feeds it to
+// the shared RateLimiter.
+func (c *Client) recordRateLimit(dataBytes []byte) {
+	var rl struct {
+		RateLimit *struct {
+			Remaining int       `json:"remaining"`
+			ResetAt   time.Time `json:"resetAt"`
+		} `json:"rateLimit"`
 	}
 
-	return nil
+	if err := json.Unmarshal(dataBytes, &rl); err != nil || rl.RateLimit == nil {
+		return
+	}
+
+	c.rateLimiter.Update(rl.RateLimit.Remaining, rl.RateLimit.ResetAt)
+}
+
+func isSecondaryRateLimitError(errs []GraphQLError) bool {
+	for _, e := range errs {
+		if strings.Contains(e.Message, secondaryRateLimitMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffSleep waits an exponentially increasing, jittered delay before
+// retry attempt n (1-indexed: the first retry is attempt 1).
+func backoffSleep(ctx context.Context, attempt int) error {
+	base := time.Duration(1<<uint(attempt)) * 250 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(base)))
+
+	timer := time.NewTimer(base + jitter)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
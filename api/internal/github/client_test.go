@@ -0,0 +1,148 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a Client pointed at server with no real sleeps
+// between retries, so retry-loop tests run instantly.
+func newTestClient(server *httptest.Server) *Client {
+	c := NewClient("test-token")
+	c.httpClient = server.Client()
+	c.endpoint = server.URL
+	c.backoff = func(ctx context.Context, attempt int) error { return nil }
+	return c
+}
+
+func TestQuerySucceedsOnFirstAttempt(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(GraphQLResponse{Data: map[string]interface{}{"ok": true}})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	if err := c.Query(context.Background(), "query {}", nil, &result); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestQueryRetriesOnBadGatewayThenSucceeds(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(GraphQLResponse{Data: map[string]interface{}{"ok": true}})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	if err := c.Query(context.Background(), "query {}", nil, &result); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
+
+func TestQueryDoesNotRetryOnNonRetryableStatus(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	err := c.Query(context.Background(), "query {}", nil, &result)
+	if err == nil {
+		t.Fatal("Query: expected error, got nil")
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 (should not retry on 401)", requests)
+	}
+}
+
+func TestQueryRetriesOnSecondaryRateLimitThenSucceeds(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 2 {
+			json.NewEncoder(w).Encode(GraphQLResponse{
+				Errors: []GraphQLError{{Message: secondaryRateLimitMessage + ", please retry later"}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(GraphQLResponse{Data: map[string]interface{}{"ok": true}})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	if err := c.Query(context.Background(), "query {}", nil, &result); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+func TestQueryDoesNotRetryOnOtherGraphQLErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(GraphQLResponse{
+			Errors: []GraphQLError{{Message: "field not found"}},
+		})
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	err := c.Query(context.Background(), "query {}", nil, &result)
+	if err == nil {
+		t.Fatal("Query: expected error, got nil")
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 (should not retry on a non-rate-limit GraphQL error)", requests)
+	}
+}
+
+func TestQueryHonorsMaxQueryAttempts(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	var result map[string]interface{}
+	err := c.Query(context.Background(), "query {}", nil, &result)
+	if err == nil {
+		t.Fatal("Query: expected error, got nil")
+	}
+	if requests != maxQueryAttempts {
+		t.Errorf("got %d requests, want %d (maxQueryAttempts)", requests, maxQueryAttempts)
+	}
+	wantErr := fmt.Sprintf("query failed after %d attempts", maxQueryAttempts)
+	if got := err.Error(); !strings.Contains(got, wantErr) {
+		t.Errorf("error = %q, want it to contain %q", got, wantErr)
+	}
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DockerfileScanResult pairs a repository with the Dockerfiles
+// ScanDockerfiles fetched for it, or the error encountered doing so.
+type DockerfileScanResult struct {
+	Repository  Repository
+	Dockerfiles []DockerfileRecord
+	Err         error
+}
+
+// ScanDockerfiles fetches Dockerfiles for repos concurrently across a
+// bounded pool of concurrency workers, sharing the Client's rate
+// limiter and retry behavior. Results stream back on the returned
+// channel in completion order (not input order) as each worker
+// finishes, and the channel closes once every repository has been
+// processed - callers can range over it to know the whole batch is
+// done before checkpointing.
+func (c *Client) ScanDockerfiles(ctx context.Context, repos []Repository, concurrency int) <-chan DockerfileScanResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan Repository)
+	results := make(chan DockerfileScanResult)
+
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for repo := range jobs {
+				results <- c.scanOne(ctx, repo)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, repo := range repos {
+			select {
+			case jobs <- repo:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func (c *Client) scanOne(ctx context.Context, repo Repository) DockerfileScanResult {
+	parts := strings.Split(repo.FullName, "/")
+	if len(parts) != 2 {
+		return DockerfileScanResult{Repository: repo, Err: fmt.Errorf("unexpected repository name %q", repo.FullName)}
+	}
+
+	dockerfiles, err := c.FetchDockerfiles(ctx, parts[0], parts[1])
+	return DockerfileScanResult{Repository: repo, Dockerfiles: dockerfiles, Err: err}
+}
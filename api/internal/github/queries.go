@@ -9,6 +9,11 @@ import (
 const (
 	SearchStarsRepositoriesQuery = `
 		query searchStarsRepositories($query: String!, $first: Int!, $after: String) {
+			rateLimit {
+				remaining
+				resetAt
+				cost
+			}
 			search(
 				first: $first,
 				query: $query,
@@ -33,34 +38,63 @@ const (
 		}
 	`
 
+	// GetRepositoryFilesQuery fetches the entries of a single tree via the
+	// Git object API. findDockerfilePaths calls this once per directory,
+	// breadth-first up to Client.MaxTreeDepth, so monorepos with deeply
+	// nested Dockerfiles (e.g. services/*/Dockerfile) are still discovered.
 	GetRepositoryFilesQuery = `
-		query getRepositoryFiles($owner: String!, $name: String!) {
+		query getRepositoryFiles($owner: String!, $name: String!, $expression: String!) {
+			rateLimit {
+				remaining
+				resetAt
+				cost
+			}
 			repository(owner: $owner, name: $name) {
-				defaultBranchRef {
-					target {
-						... on Commit {
-							tree {
-								entries {
-									name
-									type
-									object {
-										... on Tree {
-											entries {
-												name
-												type
-											}
-										}
-									}
-								}
-							}
+				object(expression: $expression) {
+					... on Tree {
+						entries {
+							name
+							type
+							path
 						}
 					}
 				}
 			}
 		}
 	`
+
+	// GetBlobContentQuery retrieves the raw text of a single file via the
+	// Git object API (HEAD:path style expressions).
+	GetBlobContentQuery = `
+		query getBlobContent($owner: String!, $name: String!, $expression: String!) {
+			rateLimit {
+				remaining
+				resetAt
+				cost
+			}
+			repository(owner: $owner, name: $name) {
+				object(expression: $expression) {
+					... on Blob {
+						text
+					}
+				}
+			}
+		}
+	`
 )
 
+// defaultMaxTreeDepth is the Client.MaxTreeDepth every NewClient starts
+// with.
+const defaultMaxTreeDepth = 4
+
+// DockerfileRecord is the raw result of fetching a single Dockerfile's
+// contents from a repository. internal/dockerfile turns Content into a
+// structured record.
+type DockerfileRecord struct {
+	Path    string
+	Content string
+}
+
 func (c *Client) GetNextRepositories(ctx context.Context, currentCursor string) (*RepositoriesResponse, error) {
 	variables := map[string]interface{}{
 		"query": "docker sort:stars-desc in:readme",
@@ -90,50 +124,109 @@ func isDockerfile(filename string) bool {
 	return strings.Contains(lowerName, "dockerfile")
 }
 
-func (c *Client) HasDockerfile(ctx context.Context, owner, name string) (bool, error) {
-	variables := map[string]interface{}{
-		"owner": owner,
-		"name":  name,
+// findDockerfilePaths walks the repository's default branch tree
+// breadth-first, starting at the root, looking for Dockerfiles. It
+// descends up to c.MaxTreeDepth directory levels so monorepos laid out
+// as services/*/Dockerfile are still found.
+func (c *Client) findDockerfilePaths(ctx context.Context, owner, name string) ([]string, error) {
+	type treeEntry struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Path string `json:"path"`
 	}
 
-	var response struct {
-		Repository struct {
-			DefaultBranchRef struct {
-				Target struct {
-					Tree struct {
-						Entries []struct {
-							Name   string `json:"name"`
-							Type   string `json:"type"`
-							Object struct {
-								Entries []struct {
-									Name string `json:"name"`
-									Type string `json:"type"`
-								} `json:"entries"`
-							} `json:"object"`
-						} `json:"entries"`
-					} `json:"tree"`
-				} `json:"target"`
-			} `json:"defaultBranchRef"`
-		} `json:"repository"`
+	var paths []string
+	queue := []string{"HEAD:"}
+
+	for depth := 0; len(queue) > 0 && depth <= c.MaxTreeDepth; depth++ {
+		var next []string
+
+		for _, expression := range queue {
+			variables := map[string]interface{}{
+				"owner":      owner,
+				"name":       name,
+				"expression": expression,
+			}
+
+			var response struct {
+				Repository struct {
+					Object struct {
+						Entries []treeEntry `json:"entries"`
+					} `json:"object"`
+				} `json:"repository"`
+			}
+
+			if err := c.Query(ctx, GetRepositoryFilesQuery, variables, &response); err != nil {
+				return nil, fmt.Errorf("failed to list tree %q: %w", expression, err)
+			}
+
+			for _, entry := range response.Repository.Object.Entries {
+				switch entry.Type {
+				case "blob":
+					if isDockerfile(entry.Name) {
+						paths = append(paths, entry.Path)
+					}
+				case "tree":
+					next = append(next, "HEAD:"+entry.Path)
+				}
+			}
+		}
+
+		queue = next
 	}
 
-	if err := c.Query(ctx, GetRepositoryFilesQuery, variables, &response); err != nil {
-		return false, fmt.Errorf("failed to get repository files: %w", err)
+	return paths, nil
+}
+
+// HasDockerfile reports whether the repository contains a Dockerfile
+// anywhere in its tree, up to c.MaxTreeDepth directory levels deep.
+func (c *Client) HasDockerfile(ctx context.Context, owner, name string) (bool, error) {
+	paths, err := c.findDockerfilePaths(ctx, owner, name)
+	if err != nil {
+		return false, err
+	}
+	return len(paths) > 0, nil
+}
+
+// FetchDockerfiles finds every Dockerfile in the repository's tree and
+// retrieves its raw text via the Git blob API. Callers pass the Content
+// of each record through internal/dockerfile.Parse to get structured
+// fields.
+func (c *Client) FetchDockerfiles(ctx context.Context, owner, name string) ([]DockerfileRecord, error) {
+	paths, err := c.findDockerfilePaths(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dockerfiles: %w", err)
 	}
 
-	for _, entry := range response.Repository.DefaultBranchRef.Target.Tree.Entries {
-		if isDockerfile(entry.Name) {
-			return true, nil
+	records := make([]DockerfileRecord, 0, len(paths))
+	for _, path := range paths {
+		variables := map[string]interface{}{
+			"owner":      owner,
+			"name":       name,
+			"expression": "HEAD:" + path,
 		}
 
-		if entry.Type == "tree" {
-			for _, subEntry := range entry.Object.Entries {
-				if isDockerfile(subEntry.Name) {
-					return true, nil
-				}
-			}
+		var response struct {
+			Repository struct {
+				Object struct {
+					Text *string `json:"text"`
+				} `json:"object"`
+			} `json:"repository"`
 		}
+
+		if err := c.Query(ctx, GetBlobContentQuery, variables, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch blob %q: %w", path, err)
+		}
+
+		if response.Repository.Object.Text == nil {
+			continue
+		}
+
+		records = append(records, DockerfileRecord{
+			Path:    path,
+			Content: *response.Repository.Object.Text,
+		})
 	}
 
-	return false, nil
+	return records, nil
 }
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitThreshold is the remaining-points floor below which
+// every worker pauses until GitHub's rate limit window resets. GitHub's
+// GraphQL budget is 5000 points/hour by default; stopping well above
+// zero leaves room for the in-flight requests of other workers.
+const defaultRateLimitThreshold = 100
+
+// RateLimiter tracks the rateLimit { remaining, resetAt } block GitHub
+// returns on every GraphQL response and makes callers wait once the
+// remaining budget drops below a threshold, so a worker pool backs off
+// together instead of each worker discovering exhaustion on its own.
+type RateLimiter struct {
+	threshold int
+
+	mu        sync.Mutex
+	remaining int
+	resetAt   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that pauses callers once the
+// last-observed remaining budget drops below threshold.
+func NewRateLimiter(threshold int) *RateLimiter {
+	return &RateLimiter{threshold: threshold}
+}
+
+// Update records the latest rateLimit block observed from a response.
+func (r *RateLimiter) Update(remaining int, resetAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remaining = remaining
+	r.resetAt = resetAt
+}
+
+// WaitIfNeeded blocks until resetAt if the last observed remaining
+// budget was below the configured threshold.
+func (r *RateLimiter) WaitIfNeeded(ctx context.Context) error {
+	r.mu.Lock()
+	remaining, resetAt := r.remaining, r.resetAt
+	r.mu.Unlock()
+
+	if resetAt.IsZero() || remaining >= r.threshold {
+		return nil
+	}
+
+	wait := time.Until(resetAt)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
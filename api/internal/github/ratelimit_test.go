@@ -0,0 +1,70 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWaitIfNeeded(t *testing.T) {
+	t.Run("does not wait above threshold", func(t *testing.T) {
+		r := NewRateLimiter(100)
+		r.Update(500, time.Now().Add(time.Hour))
+
+		done := make(chan error, 1)
+		go func() { done <- r.WaitIfNeeded(context.Background()) }()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("WaitIfNeeded() = %v, want nil", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("WaitIfNeeded blocked despite remaining budget above threshold")
+		}
+	})
+
+	t.Run("does not wait before any Update", func(t *testing.T) {
+		r := NewRateLimiter(100)
+
+		done := make(chan error, 1)
+		go func() { done <- r.WaitIfNeeded(context.Background()) }()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("WaitIfNeeded() = %v, want nil", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("WaitIfNeeded blocked with a zero resetAt")
+		}
+	})
+
+	t.Run("blocks until resetAt once below threshold", func(t *testing.T) {
+		r := NewRateLimiter(100)
+		r.Update(10, time.Now().Add(50*time.Millisecond))
+
+		start := time.Now()
+		if err := r.WaitIfNeeded(context.Background()); err != nil {
+			t.Fatalf("WaitIfNeeded() = %v, want nil", err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("WaitIfNeeded returned after %v, want >= 50ms", elapsed)
+		}
+	})
+
+	t.Run("returns ctx error if cancelled before reset", func(t *testing.T) {
+		r := NewRateLimiter(100)
+		r.Update(10, time.Now().Add(time.Hour))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		if err := r.WaitIfNeeded(ctx); err != context.Canceled {
+			t.Fatalf("WaitIfNeeded() = %v, want context.Canceled", err)
+		}
+	})
+}